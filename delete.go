@@ -0,0 +1,138 @@
+package s3meta
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+// maxDeleteBatch is the most objects S3 accepts in a single
+// multi-object delete request.
+const maxDeleteBatch = 1000
+
+type deleteObject struct {
+	Key string `xml:"Key"`
+}
+
+type deleteRequest struct {
+	XMLName xml.Name       `xml:"Delete"`
+	Objects []deleteObject `xml:"Object"`
+}
+
+// DeletedObject is a single key S3 confirmed removing.
+type DeletedObject struct {
+	Key string `xml:"Key"`
+}
+
+// DeleteError is a single key S3 failed to remove.
+type DeleteError struct {
+	Key     string `xml:"Key"`
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// MultiDeleteResult is the response to a bulk DeleteS3Objects call.
+type MultiDeleteResult struct {
+	XMLName xml.Name        `xml:"DeleteResult"`
+	Deleted []DeletedObject `xml:"Deleted"`
+	Errors  []DeleteError   `xml:"Error"`
+}
+
+// DeleteS3Object removes a single object from the bucket.
+func (b *Bucket) DeleteS3Object(key string) error {
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key
+	req, err := http.NewRequest("DELETE", fullPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := b.authDoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 && resp.StatusCode != 204 {
+		return errors.New(resp.Status + string(body))
+	}
+
+	return nil
+}
+
+// DeleteS3Objects removes up to len(keys) objects in one or more
+// multi-object delete requests, chunked to maxDeleteBatch keys each,
+// and aggregates the per-key results across all chunks.
+func (b *Bucket) DeleteS3Objects(keys []string) (*MultiDeleteResult, error) {
+	result := &MultiDeleteResult{}
+
+	for len(keys) > 0 {
+		n := maxDeleteBatch
+		if n > len(keys) {
+			n = len(keys)
+		}
+
+		batchResult, err := b.deleteS3ObjectBatch(keys[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		result.Deleted = append(result.Deleted, batchResult.Deleted...)
+		result.Errors = append(result.Errors, batchResult.Errors...)
+
+		keys = keys[n:]
+	}
+
+	return result, nil
+}
+
+func (b *Bucket) deleteS3ObjectBatch(keys []string) (*MultiDeleteResult, error) {
+	objects := make([]deleteObject, len(keys))
+	for i, k := range keys {
+		objects[i] = deleteObject{Key: k}
+	}
+
+	payload, err := xml.Marshal(deleteRequest{Objects: objects})
+	if err != nil {
+		return nil, err
+	}
+
+	fullPath := b.scheme() + "://" + b.Name + b.Base + "?delete"
+	req, err := http.NewRequest("POST", fullPath, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	sum := md5.Sum(payload)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	resp, err := b.authDoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, errors.New(resp.Status + string(body))
+	}
+
+	result := &MultiDeleteResult{}
+	if err = xml.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
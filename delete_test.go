@@ -0,0 +1,112 @@
+package s3meta
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+// deleteFakeServer records every multi-object delete request it
+// receives, confirming the Content-MD5 header matches the body that
+// was actually sent, and echoes back a Deleted entry per key.
+type deleteFakeServer struct {
+	mu       sync.Mutex
+	requests []deleteRequest
+}
+
+func (s *deleteFakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	sum := md5.Sum(body)
+	want := base64.StdEncoding.EncodeToString(sum[:])
+	if got := r.Header.Get("Content-MD5"); got != want {
+		http.Error(w, fmt.Sprintf("Content-MD5 mismatch: got %s, want %s", got, want), 400)
+		return
+	}
+
+	var req deleteRequest
+	if err := xml.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	result := MultiDeleteResult{}
+	for _, obj := range req.Objects {
+		result.Deleted = append(result.Deleted, DeletedObject{Key: obj.Key})
+	}
+
+	out, err := xml.Marshal(result)
+	if err != nil {
+		http.Error(w, err.Error(), 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(200)
+	w.Write(out)
+}
+
+func deleteTestBucket(t *testing.T, ts *httptest.Server) *Bucket {
+	t.Helper()
+
+	host, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("url.Parse: %s", err)
+	}
+
+	return &Bucket{
+		Name:        host.Host,
+		Base:        "/",
+		Key:         "key",
+		Secret:      "secret",
+		Scheme:      "http",
+		MaxAttempts: 1,
+	}
+}
+
+func TestDeleteS3ObjectsChunksOverBatchLimit(t *testing.T) {
+	server := &deleteFakeServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := deleteTestBucket(t, ts)
+
+	keys := make([]string, maxDeleteBatch+250)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+	}
+
+	result, err := bucket.DeleteS3Objects(keys)
+	if err != nil {
+		t.Fatalf("DeleteS3Objects: %s", err)
+	}
+
+	server.mu.Lock()
+	requests := server.requests
+	server.mu.Unlock()
+
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if len(requests[0].Objects) != maxDeleteBatch {
+		t.Errorf("first batch had %d keys, want %d", len(requests[0].Objects), maxDeleteBatch)
+	}
+	if len(requests[1].Objects) != 250 {
+		t.Errorf("second batch had %d keys, want 250", len(requests[1].Objects))
+	}
+
+	if len(result.Deleted) != len(keys) {
+		t.Errorf("got %d deleted keys, want %d", len(result.Deleted), len(keys))
+	}
+}
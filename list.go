@@ -0,0 +1,202 @@
+package s3meta
+
+import (
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Owner is the bucket/object owner as reported by S3.
+type Owner struct {
+	ID          string `xml:"ID"`
+	DisplayName string `xml:"DisplayName"`
+}
+
+// CommonPrefix is a rolled-up key prefix returned when a delimiter is
+// used, standing in for every key sharing that prefix.
+type CommonPrefix struct {
+	Prefix string `xml:"Prefix"`
+}
+
+// BucketItem is a single object as returned in a bucket listing.
+type BucketItem struct {
+	Key          string    `xml:"Key"`
+	LastModified time.Time `xml:"LastModified"`
+	ETag         string    `xml:"ETag"`
+	Size         int64     `xml:"Size"`
+	StorageClass string    `xml:"StorageClass"`
+	Owner        Owner     `xml:"Owner"`
+
+	// Body is not populated by ListS3BucketObjects; it exists so
+	// callers can stash the object's contents alongside its metadata.
+	Body string
+}
+
+// ListBucketResult mirrors S3's ListObjects/ListObjectsV2 response.
+// Marker/NextMarker are populated by ListS3BucketObjects;
+// ContinuationToken/NextContinuationToken/KeyCount are populated by
+// ListObjectsV2.
+type ListBucketResult struct {
+	XMLName xml.Name `xml:"ListBucketResult"`
+
+	Name        string `xml:"Name"`
+	Prefix      string `xml:"Prefix"`
+	Delimiter   string `xml:"Delimiter"`
+	MaxKeys     int    `xml:"MaxKeys"`
+	IsTruncated bool   `xml:"IsTruncated"`
+
+	Marker     string `xml:"Marker"`
+	NextMarker string `xml:"NextMarker"`
+
+	ContinuationToken     string `xml:"ContinuationToken"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	KeyCount              int    `xml:"KeyCount"`
+
+	Contents       []BucketItem   `xml:"Contents"`
+	CommonPrefixes []CommonPrefix `xml:"CommonPrefixes"`
+}
+
+func (b *Bucket) listQuery(params url.Values) (*http.Response, error) {
+	fullPath := b.scheme() + "://" + b.Name + b.Base + "?" + params.Encode()
+	req, err := http.NewRequest("GET", fullPath, nil)
+	if err != nil {
+		return &http.Response{}, err
+	}
+
+	return b.authDoRequest(req)
+}
+
+func (b *Bucket) listResult(resp *http.Response, err error) (*ListBucketResult, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, errors.New(string(body))
+	}
+
+	result := &ListBucketResult{}
+	if err = xml.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListS3BucketObjectsResponse issues a ListObjects (v1) request with the
+// given prefix, delimiter, marker and maxKeys. Any of prefix, delimiter
+// or marker may be left empty; maxKeys of 0 omits the parameter and
+// lets S3 use its default (1000).
+func (b *Bucket) ListS3BucketObjectsResponse(prefix, delimiter, marker string, maxKeys int) (*http.Response, error) {
+	params := url.Values{}
+	if prefix != "" {
+		params.Set("prefix", prefix)
+	}
+	if delimiter != "" {
+		params.Set("delimiter", delimiter)
+	}
+	if marker != "" {
+		params.Set("marker", marker)
+	}
+	if maxKeys != 0 {
+		params.Set("max-keys", strconv.Itoa(maxKeys))
+	}
+
+	return b.listQuery(params)
+}
+
+// ListS3BucketObjects lists objects under prefix, grouping keys sharing
+// a common prefix up to delimiter into CommonPrefixes. Pass marker
+// (commonly the previous result's NextMarker) to continue a listing
+// that had IsTruncated set, and maxKeys to cap the page size (0 uses
+// S3's default).
+func (b *Bucket) ListS3BucketObjects(prefix, delimiter, marker string, maxKeys int) (*ListBucketResult, error) {
+	resp, err := b.ListS3BucketObjectsResponse(prefix, delimiter, marker, maxKeys)
+	return b.listResult(resp, err)
+}
+
+// ListObjectsV2Response issues a ListObjectsV2 (list-type=2) request.
+func (b *Bucket) ListObjectsV2Response(prefix, delimiter, continuationToken string, maxKeys int) (*http.Response, error) {
+	params := url.Values{}
+	params.Set("list-type", "2")
+	if prefix != "" {
+		params.Set("prefix", prefix)
+	}
+	if delimiter != "" {
+		params.Set("delimiter", delimiter)
+	}
+	if continuationToken != "" {
+		params.Set("continuation-token", continuationToken)
+	}
+	if maxKeys != 0 {
+		params.Set("max-keys", strconv.Itoa(maxKeys))
+	}
+
+	return b.listQuery(params)
+}
+
+// ListObjectsV2 is the ListObjectsV2 equivalent of ListS3BucketObjects,
+// continuing a truncated listing via continuationToken (the previous
+// result's NextContinuationToken) rather than a marker.
+func (b *Bucket) ListObjectsV2(prefix, delimiter, continuationToken string, maxKeys int) (*ListBucketResult, error) {
+	resp, err := b.ListObjectsV2Response(prefix, delimiter, continuationToken, maxKeys)
+	return b.listResult(resp, err)
+}
+
+// ListAll lists every object under prefix, transparently following
+// ListObjectsV2 continuation tokens, and streams each one over the
+// returned channel. The error channel receives at most one error,
+// after which both channels are closed; on success it is closed
+// without a value.
+//
+// If the caller stops ranging over the item channel before the
+// listing is exhausted, it must close stop to let the background
+// goroutine unblock and exit; otherwise it leaks for the life of the
+// listing.
+func (b *Bucket) ListAll(prefix, delimiter string) (items <-chan BucketItem, errs <-chan error, stop chan<- struct{}) {
+	itemsCh := make(chan BucketItem)
+	errsCh := make(chan error, 1)
+	stopCh := make(chan struct{})
+
+	go func() {
+		defer close(itemsCh)
+		defer close(errsCh)
+
+		continuationToken := ""
+		for {
+			result, err := b.ListObjectsV2(prefix, delimiter, continuationToken, 0)
+			if err != nil {
+				select {
+				case errsCh <- err:
+				case <-stopCh:
+				}
+				return
+			}
+
+			for _, item := range result.Contents {
+				select {
+				case itemsCh <- item:
+				case <-stopCh:
+					return
+				}
+			}
+
+			if !result.IsTruncated {
+				return
+			}
+			continuationToken = result.NextContinuationToken
+		}
+	}()
+
+	return itemsCh, errsCh, stopCh
+}
@@ -0,0 +1,153 @@
+package s3meta
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// listFakeServer serves a canned sequence of ListObjectsV2 pages keyed
+// by the incoming continuation-token, or a 500 on every request when
+// failAlways is set.
+type listFakeServer struct {
+	pages      map[string]string
+	failAlways bool
+	calls      int
+}
+
+func (s *listFakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.calls++
+
+	if s.failAlways {
+		w.WriteHeader(500)
+		w.Write([]byte(`<Error><Code>InternalError</Code></Error>`))
+		return
+	}
+
+	token := r.URL.Query().Get("continuation-token")
+	body, ok := s.pages[token]
+	if !ok {
+		w.WriteHeader(404)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(200)
+	w.Write([]byte(body))
+}
+
+func listTestBucket(t *testing.T, ts *httptest.Server) *Bucket {
+	t.Helper()
+
+	host, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("url.Parse: %s", err)
+	}
+
+	return &Bucket{
+		Name:        host.Host,
+		Base:        "/",
+		Key:         "key",
+		Secret:      "secret",
+		Scheme:      "http",
+		MaxAttempts: 1,
+	}
+}
+
+func TestListAllFollowsContinuationTokensAcrossPages(t *testing.T) {
+	server := &listFakeServer{pages: map[string]string{
+		"": `<ListBucketResult>
+			<IsTruncated>true</IsTruncated>
+			<NextContinuationToken>tok1</NextContinuationToken>
+			<Contents><Key>one</Key></Contents>
+			<Contents><Key>two</Key></Contents>
+		</ListBucketResult>`,
+		"tok1": `<ListBucketResult>
+			<IsTruncated>false</IsTruncated>
+			<Contents><Key>three</Key></Contents>
+		</ListBucketResult>`,
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := listTestBucket(t, ts)
+	items, errs, _ := bucket.ListAll("", "")
+
+	var got []string
+	for item := range items {
+		got = append(got, item.Key)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	want := []string{"one", "two", "three"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i, k := range want {
+		if got[i] != k {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestListAllStopsCleanlyMidStream(t *testing.T) {
+	server := &listFakeServer{pages: map[string]string{
+		"": `<ListBucketResult>
+			<IsTruncated>true</IsTruncated>
+			<NextContinuationToken>tok1</NextContinuationToken>
+			<Contents><Key>one</Key></Contents>
+			<Contents><Key>two</Key></Contents>
+		</ListBucketResult>`,
+		"tok1": `<ListBucketResult>
+			<IsTruncated>false</IsTruncated>
+			<Contents><Key>three</Key></Contents>
+		</ListBucketResult>`,
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := listTestBucket(t, ts)
+	items, _, stop := bucket.ListAll("", "")
+
+	<-items // take exactly one item, then bail
+	close(stop)
+
+	select {
+	case _, ok := <-items:
+		if ok {
+			// Drain the rest; the channel must still close promptly.
+			for range items {
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatal("items channel did not close after stop was closed; goroutine leaked")
+	}
+}
+
+func TestListAllForwardsErrorExactlyOnce(t *testing.T) {
+	server := &listFakeServer{failAlways: true}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := listTestBucket(t, ts)
+	items, errs, _ := bucket.ListAll("", "")
+
+	for range items {
+		t.Error("did not expect any items")
+	}
+
+	err := <-errs
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if _, ok := <-errs; ok {
+		t.Error("expected errs to be closed after the first error")
+	}
+}
@@ -0,0 +1,265 @@
+package s3meta
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+)
+
+// Part is a single uploaded part of a multipart upload, as required by
+// the CompleteMultipartUpload request body.
+type Part struct {
+	PartNumber int    `xml:"PartNumber"`
+	ETag       string `xml:"ETag"`
+}
+
+// completeMultipartUpload is the XML body sent to complete an upload.
+// S3 requires parts to be listed in ascending PartNumber order.
+type completeMultipartUpload struct {
+	XMLName xml.Name `xml:"CompleteMultipartUpload"`
+	Parts   []Part   `xml:"Part"`
+}
+
+type initiateMultipartUploadResult struct {
+	XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+	UploadID string   `xml:"UploadId"`
+}
+
+// MultipartUpload tracks the state of a single in-progress multipart
+// upload: the bucket and key it targets, its UploadID, and the parts
+// uploaded so far.
+type MultipartUpload struct {
+	bucket   *Bucket
+	Key      string
+	UploadID string
+
+	mu    sync.Mutex
+	parts []Part
+}
+
+// InitiateMultipartUpload starts a multipart upload for key, optionally
+// attaching user meta data, and returns a handle for uploading parts.
+func (b *Bucket) InitiateMultipartUpload(key string, meta map[string]string) (*MultipartUpload, error) {
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key + "?uploads"
+	req, err := http.NewRequest("POST", fullPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range meta {
+		req.Header.Add("x-amz-meta-"+k, v)
+	}
+
+	resp, err := b.authDoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != 200 {
+		return nil, errors.New(resp.Status + string(body))
+	}
+
+	result := &initiateMultipartUploadResult{}
+	if err = xml.Unmarshal(body, result); err != nil {
+		return nil, err
+	}
+
+	return &MultipartUpload{
+		bucket:   b,
+		Key:      key,
+		UploadID: result.UploadID,
+	}, nil
+}
+
+// UploadPart uploads a single part and records its ETag for the eventual
+// CompleteMultipartUpload call. It is safe to call concurrently.
+func (u *MultipartUpload) UploadPart(partNum int, data []byte) (etag string, err error) {
+	fullPath := fmt.Sprintf("%s://%s%s%s?partNumber=%d&uploadId=%s", u.bucket.scheme(), u.bucket.Name, u.bucket.Base, u.Key, partNum, u.UploadID)
+	req, err := http.NewRequest("PUT", fullPath, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := u.bucket.authDoRequest(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != 200 {
+		return "", errors.New(resp.Status + string(body))
+	}
+
+	etag = resp.Header.Get("ETag")
+
+	u.mu.Lock()
+	u.parts = append(u.parts, Part{PartNumber: partNum, ETag: etag})
+	u.mu.Unlock()
+
+	return etag, nil
+}
+
+// CompleteMultipartUpload assembles the uploaded parts into the final
+// object, submitting them to S3 in ascending part-number order.
+func (u *MultipartUpload) CompleteMultipartUpload() error {
+	u.mu.Lock()
+	parts := make([]Part, len(u.parts))
+	copy(parts, u.parts)
+	u.mu.Unlock()
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+
+	payload, err := xml.Marshal(completeMultipartUpload{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	fullPath := fmt.Sprintf("%s://%s%s%s?uploadId=%s", u.bucket.scheme(), u.bucket.Name, u.bucket.Base, u.Key, u.UploadID)
+	req, err := http.NewRequest("POST", fullPath, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	resp, err := u.bucket.authDoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 {
+		return errors.New(resp.Status + string(body))
+	}
+
+	return nil
+}
+
+// AbortMultipartUpload cancels the upload and releases any parts
+// already stored by S3 for it.
+func (u *MultipartUpload) AbortMultipartUpload() error {
+	fullPath := fmt.Sprintf("%s://%s%s%s?uploadId=%s", u.bucket.scheme(), u.bucket.Name, u.bucket.Base, u.Key, u.UploadID)
+	req, err := http.NewRequest("DELETE", fullPath, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := u.bucket.authDoRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 204 && resp.StatusCode != 200 {
+		return errors.New(resp.Status + string(body))
+	}
+
+	return nil
+}
+
+// PutS3ObjectStream uploads the contents of r as a multipart upload,
+// reading partSize-sized chunks and fanning them out to a pool of
+// concurrency workers. It lets callers push objects larger than the
+// practical single-PUT ceiling (~100 MB) without buffering the whole
+// object in memory first.
+func (b *Bucket) PutS3ObjectStream(key string, r io.Reader, partSize int64, concurrency int) error {
+	if partSize <= 0 {
+		return errors.New("s3meta: partSize must be positive")
+	}
+	if concurrency <= 0 {
+		return errors.New("s3meta: concurrency must be positive")
+	}
+
+	upload, err := b.InitiateMultipartUpload(key, nil)
+	if err != nil {
+		return err
+	}
+
+	type job struct {
+		partNum int
+		data    []byte
+	}
+
+	jobs := make(chan job)
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				if _, err := upload.UploadPart(j.partNum, j.data); err != nil {
+					select {
+					case errs <- err:
+					default:
+					}
+				}
+			}
+		}()
+	}
+
+	var readErr error
+	partNum := 1
+readLoop:
+	for {
+		buf := make([]byte, partSize)
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			select {
+			case jobs <- job{partNum: partNum, data: buf[:n]}:
+				partNum++
+			case readErr = <-errs:
+				break readLoop
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			readErr = err
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if readErr == nil {
+		select {
+		case readErr = <-errs:
+		default:
+		}
+	}
+
+	if readErr != nil {
+		upload.AbortMultipartUpload()
+		return readErr
+	}
+
+	return upload.CompleteMultipartUpload()
+}
@@ -0,0 +1,206 @@
+package s3meta
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// multipartFakeServer is a minimal stand-in for S3's multipart-upload
+// endpoints, enough to drive InitiateMultipartUpload, UploadPart,
+// CompleteMultipartUpload, AbortMultipartUpload and PutS3ObjectStream.
+type multipartFakeServer struct {
+	mu sync.Mutex
+
+	parts        map[int][]byte
+	completeBody []byte
+	aborted      bool
+
+	// failPart, when non-zero, makes UploadPart fail for that part
+	// number with a 500.
+	failPart int
+}
+
+func newMultipartFakeServer() *multipartFakeServer {
+	return &multipartFakeServer{parts: make(map[int][]byte)}
+}
+
+func hasQueryKey(q url.Values, key string) bool {
+	_, ok := q[key]
+	return ok
+}
+
+func (s *multipartFakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	switch {
+	case r.Method == "POST" && hasQueryKey(q, "uploads"):
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(200)
+		w.Write([]byte(`<InitiateMultipartUploadResult><UploadId>test-upload-id</UploadId></InitiateMultipartUploadResult>`))
+
+	case r.Method == "PUT" && q.Get("partNumber") != "":
+		partNum, _ := strconv.Atoi(q.Get("partNumber"))
+		body, _ := ioutil.ReadAll(r.Body)
+
+		s.mu.Lock()
+		fail := s.failPart != 0 && partNum == s.failPart
+		if !fail {
+			s.parts[partNum] = body
+		}
+		s.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(500)
+			return
+		}
+
+		w.Header().Set("ETag", fmt.Sprintf("\"etag-%d\"", partNum))
+		w.WriteHeader(200)
+
+	case r.Method == "POST" && q.Get("uploadId") != "":
+		body, _ := ioutil.ReadAll(r.Body)
+		s.mu.Lock()
+		s.completeBody = body
+		s.mu.Unlock()
+		w.WriteHeader(200)
+
+	case r.Method == "DELETE" && q.Get("uploadId") != "":
+		s.mu.Lock()
+		s.aborted = true
+		s.mu.Unlock()
+		w.WriteHeader(204)
+
+	default:
+		w.WriteHeader(404)
+	}
+}
+
+func multipartTestBucket(t *testing.T, ts *httptest.Server) *Bucket {
+	t.Helper()
+
+	host, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("url.Parse: %s", err)
+	}
+
+	return &Bucket{
+		Name:        host.Host,
+		Base:        "/",
+		Key:         "key",
+		Secret:      "secret",
+		Scheme:      "http",
+		MaxAttempts: 1,
+	}
+}
+
+func TestCompleteMultipartUploadOrdersPartsAscending(t *testing.T) {
+	server := newMultipartFakeServer()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := multipartTestBucket(t, ts)
+
+	upload, err := bucket.InitiateMultipartUpload("file.txt", nil)
+	if err != nil {
+		t.Fatalf("InitiateMultipartUpload: %s", err)
+	}
+
+	// Upload part 2 before part 1 to prove completion doesn't depend
+	// on upload order.
+	if _, err := upload.UploadPart(2, []byte("second")); err != nil {
+		t.Fatalf("UploadPart(2): %s", err)
+	}
+	if _, err := upload.UploadPart(1, []byte("first")); err != nil {
+		t.Fatalf("UploadPart(1): %s", err)
+	}
+
+	if err := upload.CompleteMultipartUpload(); err != nil {
+		t.Fatalf("CompleteMultipartUpload: %s", err)
+	}
+
+	var sent completeMultipartUpload
+	server.mu.Lock()
+	body := server.completeBody
+	server.mu.Unlock()
+
+	if err := xml.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("unmarshal completion body: %s", err)
+	}
+
+	if len(sent.Parts) != 2 {
+		t.Fatalf("got %d parts, want 2", len(sent.Parts))
+	}
+	if sent.Parts[0].PartNumber != 1 || sent.Parts[1].PartNumber != 2 {
+		t.Errorf("parts not in ascending order: %+v", sent.Parts)
+	}
+}
+
+func TestPutS3ObjectStreamAbortsOnPartFailure(t *testing.T) {
+	server := newMultipartFakeServer()
+	server.failPart = 2
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := multipartTestBucket(t, ts)
+
+	data := bytes.Repeat([]byte("a"), 25)
+	err := bucket.PutS3ObjectStream("file.txt", bytes.NewReader(data), 10, 1)
+	if err == nil {
+		t.Fatal("expected an error from a failing part upload")
+	}
+
+	server.mu.Lock()
+	aborted := server.aborted
+	server.mu.Unlock()
+
+	if !aborted {
+		t.Error("expected AbortMultipartUpload to be called after the part failure")
+	}
+}
+
+func TestPutS3ObjectStreamShortFinalPart(t *testing.T) {
+	server := newMultipartFakeServer()
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	bucket := multipartTestBucket(t, ts)
+
+	data := bytes.Repeat([]byte("b"), 25)
+	if err := bucket.PutS3ObjectStream("file.txt", bytes.NewReader(data), 10, 2); err != nil {
+		t.Fatalf("PutS3ObjectStream: %s", err)
+	}
+
+	server.mu.Lock()
+	parts := server.parts
+	completeBody := server.completeBody
+	server.mu.Unlock()
+
+	if len(parts) != 3 {
+		t.Fatalf("got %d parts, want 3", len(parts))
+	}
+	if len(parts[1]) != 10 || len(parts[2]) != 10 || len(parts[3]) != 5 {
+		t.Errorf("unexpected part sizes: part1=%d part2=%d part3=%d", len(parts[1]), len(parts[2]), len(parts[3]))
+	}
+
+	var sent completeMultipartUpload
+	if err := xml.Unmarshal(completeBody, &sent); err != nil {
+		t.Fatalf("unmarshal completion body: %s", err)
+	}
+	if len(sent.Parts) != 3 {
+		t.Fatalf("got %d completed parts, want 3", len(sent.Parts))
+	}
+	for i, p := range sent.Parts {
+		if p.PartNumber != i+1 {
+			t.Errorf("parts not in ascending order: %+v", sent.Parts)
+			break
+		}
+	}
+}
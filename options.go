@@ -0,0 +1,115 @@
+package s3meta
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"net/http"
+)
+
+// Options controls per-request behavior for PutS3ObjectWithOptions:
+// server-side encryption, cache/content headers, storage class, ACL
+// and user meta data.
+type Options struct {
+	// SSE requests S3-managed server-side encryption
+	// (x-amz-server-side-encryption: AES256).
+	SSE bool
+
+	// SSECustomerKey is the raw (not base64-encoded) customer-provided
+	// encryption key for SSE-C. When set, SSECustomerAlgorithm
+	// defaults to "AES256" and SSECustomerKeyMD5 is computed
+	// automatically if left empty.
+	SSECustomerAlgorithm string
+	SSECustomerKey       []byte
+	SSECustomerKeyMD5    string
+
+	ContentEncoding    string
+	CacheControl       string
+	ContentDisposition string
+	ContentType        string
+	StorageClass       string
+	ACL                string
+	Meta               map[string]string
+}
+
+// PutS3ObjectWithOptionsResponse is the Response-returning variant of
+// PutS3ObjectWithOptions.
+func (b *Bucket) PutS3ObjectWithOptionsResponse(key string, body []byte, opts Options) (*http.Response, error) {
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key
+	req, err := http.NewRequest("PUT", fullPath, bytes.NewReader(body))
+	if err != nil {
+		return &http.Response{}, err
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	for k, v := range opts.Meta {
+		req.Header.Set("x-amz-meta-"+k, v)
+	}
+
+	if opts.SSE {
+		req.Header.Set("x-amz-server-side-encryption", "AES256")
+	}
+
+	if len(opts.SSECustomerKey) > 0 {
+		algorithm := opts.SSECustomerAlgorithm
+		if algorithm == "" {
+			algorithm = "AES256"
+		}
+		req.Header.Set("x-amz-server-side-encryption-customer-algorithm", algorithm)
+		req.Header.Set("x-amz-server-side-encryption-customer-key", base64.StdEncoding.EncodeToString(opts.SSECustomerKey))
+
+		keyMD5 := opts.SSECustomerKeyMD5
+		if keyMD5 == "" {
+			sum := md5.Sum(opts.SSECustomerKey)
+			keyMD5 = base64.StdEncoding.EncodeToString(sum[:])
+		}
+		req.Header.Set("x-amz-server-side-encryption-customer-key-md5", keyMD5)
+	}
+
+	if opts.StorageClass != "" {
+		req.Header.Set("x-amz-storage-class", opts.StorageClass)
+	}
+	if opts.ACL != "" {
+		req.Header.Set("x-amz-acl", opts.ACL)
+	}
+	if opts.ContentEncoding != "" {
+		req.Header.Set("Content-Encoding", opts.ContentEncoding)
+	}
+	if opts.CacheControl != "" {
+		req.Header.Set("Cache-Control", opts.CacheControl)
+	}
+	if opts.ContentDisposition != "" {
+		req.Header.Set("Content-Disposition", opts.ContentDisposition)
+	}
+
+	return b.authDoRequest(req)
+}
+
+// PutS3ObjectWithOptions uploads body to key, applying the headers
+// described by opts (server-side encryption, storage class, ACL,
+// cache/content headers and user meta data).
+func (b *Bucket) PutS3ObjectWithOptions(key string, body []byte, opts Options) error {
+	resp, err := b.PutS3ObjectWithOptionsResponse(key, body, opts)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != 200 {
+		return errors.New(resp.Status + string(respBody))
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package s3meta
+
+import (
+	"crypto/md5"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func optionsTestBucket(t *testing.T, ts *httptest.Server) *Bucket {
+	t.Helper()
+
+	host, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatalf("url.Parse: %s", err)
+	}
+
+	return &Bucket{
+		Name:        host.Host,
+		Base:        "/",
+		Key:         "key",
+		Secret:      "secret",
+		Scheme:      "http",
+		MaxAttempts: 1,
+	}
+}
+
+func TestPutS3ObjectWithOptionsDefaultsSSECustomerKeyMD5(t *testing.T) {
+	var gotHeader http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	bucket := optionsTestBucket(t, ts)
+
+	rawKey := []byte("0123456789abcdef0123456789abcdef")
+	err := bucket.PutS3ObjectWithOptions("file.txt", []byte("body"), Options{
+		SSECustomerKey: rawKey,
+	})
+	if err != nil {
+		t.Fatalf("PutS3ObjectWithOptions: %s", err)
+	}
+
+	sum := md5.Sum(rawKey)
+	want := base64.StdEncoding.EncodeToString(sum[:])
+
+	if got := gotHeader.Get("x-amz-server-side-encryption-customer-key-md5"); got != want {
+		t.Errorf("got customer-key-md5 %q, want %q", got, want)
+	}
+	if got := gotHeader.Get("x-amz-server-side-encryption-customer-algorithm"); got != "AES256" {
+		t.Errorf("got customer-algorithm %q, want %q", got, "AES256")
+	}
+}
+
+func TestPutS3ObjectWithOptionsSignsSSEHeadersUnderSigV4(t *testing.T) {
+	var gotHeader http.Header
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		w.WriteHeader(200)
+	}))
+	defer ts.Close()
+
+	bucket := optionsTestBucket(t, ts)
+	bucket.SignatureVersion = SigV4
+	bucket.Region = "us-east-1"
+
+	err := bucket.PutS3ObjectWithOptions("file.txt", []byte("body"), Options{SSE: true})
+	if err != nil {
+		t.Fatalf("PutS3ObjectWithOptions: %s", err)
+	}
+
+	auth := gotHeader.Get("Authorization")
+	if auth == "" {
+		t.Fatal("no Authorization header sent")
+	}
+
+	idx := strings.Index(auth, "SignedHeaders=")
+	if idx == -1 {
+		t.Fatalf("Authorization header missing SignedHeaders: %s", auth)
+	}
+	signedHeaders := auth[idx+len("SignedHeaders="):]
+	signedHeaders = signedHeaders[:strings.Index(signedHeaders, ",")]
+
+	if !containsHeader(strings.Split(signedHeaders, ";"), "x-amz-server-side-encryption") {
+		t.Errorf("x-amz-server-side-encryption not in SignedHeaders: %s", signedHeaders)
+	}
+}
+
+func containsHeader(headers []string, name string) bool {
+	for _, h := range headers {
+		if h == name {
+			return true
+		}
+	}
+	return false
+}
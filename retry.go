@@ -0,0 +1,109 @@
+package s3meta
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// retryBaseDelay and retryMaxDelay bound the decorrelated-jitter
+// backoff used between retry attempts.
+const (
+	retryBaseDelay = 100 * time.Millisecond
+	retryMaxDelay  = 20 * time.Second
+)
+
+// retryableStatusCodes are HTTP statuses S3 returns for transient
+// server-side failures.
+var retryableStatusCodes = map[int]bool{
+	500: true,
+	502: true,
+	503: true,
+	504: true,
+}
+
+// retryableAWSErrorCodes are S3 error codes worth retrying even when
+// the HTTP status itself isn't in retryableStatusCodes.
+var retryableAWSErrorCodes = map[string]bool{
+	"RequestTimeout": true,
+	"Throttling":     true,
+	"SlowDown":       true,
+	"InternalError":  true,
+}
+
+// decorrelatedJitter computes the next sleep duration given the
+// previous one, per the "decorrelated jitter" backoff algorithm:
+// sleep = min(cap, random_between(base, prev*3)).
+func decorrelatedJitter(prev time.Duration) time.Duration {
+	if prev < retryBaseDelay {
+		prev = retryBaseDelay
+	}
+
+	span := prev*3 - retryBaseDelay
+	sleep := retryBaseDelay + time.Duration(rand.Int63n(int64(span)+1))
+
+	if sleep > retryMaxDelay {
+		sleep = retryMaxDelay
+	}
+
+	return sleep
+}
+
+// shouldRetry reports whether resp represents a transient failure
+// worth retrying: a retryable HTTP status, or a retryable AWS error
+// code in the XML error body.
+func shouldRetry(resp *http.Response) bool {
+	if resp == nil {
+		return false
+	}
+
+	if retryableStatusCodes[resp.StatusCode] {
+		return true
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false
+	}
+
+	return retryableAWSErrorCodes[awsErrorCode(resp)]
+}
+
+// awsErrorCode reads resp's body looking for S3's <Error><Code>...
+// structure, then restores the body so it can still be read by the
+// caller.
+func awsErrorCode(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	var awsErr struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+	}
+	if xml.Unmarshal(body, &awsErr) != nil {
+		return ""
+	}
+
+	return awsErr.Code
+}
+
+// resetAuthHeaders strips any signing headers left over from a
+// previous attempt so authRequest regenerates them (including the
+// date) against the current time before each send.
+func resetAuthHeaders(request *http.Request) {
+	request.Header.Del("Date")
+	request.Header.Del("x-amz-date")
+	request.Header.Del("x-amz-content-sha256")
+	request.Header.Del("Authorization")
+	request.Header.Del("Host")
+}
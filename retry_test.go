@@ -0,0 +1,116 @@
+package s3meta
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDecorrelatedJitterBounds(t *testing.T) {
+	prev := time.Duration(0)
+	for i := 0; i < 1000; i++ {
+		sleep := decorrelatedJitter(prev)
+		if sleep < retryBaseDelay || sleep > retryMaxDelay {
+			t.Fatalf("decorrelatedJitter(%s) = %s, want within [%s, %s]", prev, sleep, retryBaseDelay, retryMaxDelay)
+		}
+		prev = sleep
+	}
+}
+
+func TestShouldRetry(t *testing.T) {
+	cases := []struct {
+		status int
+		body   string
+		want   bool
+	}{
+		{200, "", false},
+		{204, "", false},
+		{500, "", true},
+		{502, "", true},
+		{503, "", true},
+		{504, "", true},
+		{400, "<Error><Code>Throttling</Code></Error>", true},
+		{400, "<Error><Code>SlowDown</Code></Error>", true},
+		{400, "<Error><Code>RequestTimeout</Code></Error>", true},
+		{400, "<Error><Code>InternalError</Code></Error>", true},
+		{400, "<Error><Code>InvalidArgument</Code></Error>", false},
+		{404, "", false},
+	}
+
+	for _, c := range cases {
+		resp := &http.Response{StatusCode: c.status, Body: ioutil.NopCloser(strings.NewReader(c.body))}
+
+		if got := shouldRetry(resp); got != c.want {
+			t.Errorf("shouldRetry(status=%d, body=%q) = %v, want %v", c.status, c.body, got, c.want)
+		}
+
+		remaining, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("ReadAll after shouldRetry: %s", err)
+		}
+		if string(remaining) != c.body {
+			t.Errorf("shouldRetry consumed the body: got %q, want %q", remaining, c.body)
+		}
+	}
+}
+
+// TestAuthDoRequestRewindsBodyOnRetry checks that a PUT body is resent
+// unchanged on each retry attempt.
+func TestAuthDoRequestRewindsBodyOnRetry(t *testing.T) {
+	var attempts int32
+	var mu sync.Mutex
+	var gotBodies []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		mu.Lock()
+		gotBodies = append(gotBodies, string(body))
+		mu.Unlock()
+
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	host, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatalf("url.Parse: %s", err)
+	}
+
+	bucket := &Bucket{
+		Name:   host.Host,
+		Base:   "/",
+		Key:    "key",
+		Secret: "secret",
+		Scheme: "http",
+	}
+
+	resp, err := bucket.PutS3ObjectResponse("file.txt", []byte("hello world"))
+	if err != nil {
+		t.Fatalf("PutS3ObjectResponse: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("final status = %d, want 200", resp.StatusCode)
+	}
+	if atomic.LoadInt32(&attempts) != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+
+	for i, body := range gotBodies {
+		if body != "hello world" {
+			t.Errorf("attempt %d body = %q, want %q", i+1, body, "hello world")
+		}
+	}
+}
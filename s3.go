@@ -7,9 +7,9 @@ import (
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/base64"
-	"encoding/xml"
 	"errors"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"sort"
 	"strings"
@@ -19,9 +19,8 @@ import (
 
 
 var (
-  MaxAttempts          = 5
-	TotalAttemptTimeout  = 5 * time.Second
-	DelayBetweenAttempts = 200 * time.Millisecond
+  MaxAttempts         = 5
+	TotalAttemptTimeout = 5 * time.Second
 )
 
 // Bucket is representation of an S3 bucket.
@@ -38,10 +37,82 @@ type Bucket struct {
 	Key string
 	// S3 Secret
 	Secret string
+
+	// Region is the AWS region the bucket lives in, e.g. "eu-central-1".
+	// It is required when SignatureVersion is SigV4.
+	Region string
+
+	// SignatureVersion selects the request-signing scheme: SigV2 (the
+	// default, zero value) or SigV4. SigV2 is rejected by regions
+	// created after 2014 and by most S3-compatible services.
+	SignatureVersion int
+
+	// Scheme is the URL scheme to request over, "http" or "https". It
+	// defaults to "https" when empty.
+	Scheme string
+
+	// HTTPClient is the client used to perform requests. When nil, a
+	// client built from ConnectTimeout/ReadTimeout is used (falling
+	// back to http.DefaultClient if neither is set).
+	HTTPClient *http.Client
+
+	// ConnectTimeout bounds dialing the connection. Only used when
+	// HTTPClient is nil.
+	ConnectTimeout time.Duration
+
+	// ReadTimeout bounds each whole request, including dialing, any
+	// redirects and reading the response body. Only used when
+	// HTTPClient is nil.
+	ReadTimeout time.Duration
+
+	// MaxAttempts and TotalAttemptTimeout override the package-level
+	// retry defaults for this Bucket when non-zero. The delay between
+	// attempts is not configurable: it follows a decorrelated-jitter
+	// backoff between retryBaseDelay and retryMaxDelay.
+	MaxAttempts         int
+	TotalAttemptTimeout time.Duration
+}
+
+func (b *Bucket) scheme() string {
+	if b.Scheme == "" {
+		return "https"
+	}
+	return b.Scheme
+}
+
+func (b *Bucket) httpClient() *http.Client {
+	if b.HTTPClient != nil {
+		return b.HTTPClient
+	}
+
+	if b.ConnectTimeout == 0 && b.ReadTimeout == 0 {
+		return http.DefaultClient
+	}
+
+	return &http.Client{
+		Timeout: b.ReadTimeout,
+		Transport: &http.Transport{
+			Dial: (&net.Dialer{Timeout: b.ConnectTimeout}).Dial,
+		},
+	}
+}
+
+func (b *Bucket) maxAttempts() int {
+	if b.MaxAttempts != 0 {
+		return b.MaxAttempts
+	}
+	return MaxAttempts
+}
+
+func (b *Bucket) totalAttemptTimeout() time.Duration {
+	if b.TotalAttemptTimeout != 0 {
+		return b.TotalAttemptTimeout
+	}
+	return TotalAttemptTimeout
 }
 
 func (b *Bucket) HeadS3ObjectResponse(key string) (*http.Response, error) {
-	fullPath := "http://" + b.Name + b.Base + key
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key
 	req, err := http.NewRequest("HEAD", fullPath, nil)
 	if err != nil {
 		return &http.Response{}, err
@@ -98,7 +169,7 @@ func (b *Bucket) HeadS3ObjectWithMetaData(key string) (bl bool, data map[string]
 }
 
 func (b *Bucket) GetS3ObjectResponse(key string) (*http.Response, error) {
-	fullPath := "http://" + b.Name + b.Base + key
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key
 	req, err := http.NewRequest("GET", fullPath, nil)
 	if err != nil {
 		return &http.Response{}, err
@@ -151,66 +222,15 @@ func (b *Bucket) GetS3ObjectWithMetaData(key string) (str string, data map[strin
 	return
 }
 
-func (b *Bucket) ListS3BucketObjectsResponse(prefix string) (*http.Response, error) {
-	fullPath := "http://" + b.Name + b.Base + "?prefix=" + prefix
-	req, err := http.NewRequest("GET", fullPath, nil)
-	if err != nil {
-		return &http.Response{}, err
-	}
-	resp, err := b.authDoRequest(req)
-
-	return resp, err
-}
-
-type BucketItem struct {
-	Key          string
-	LastModified time.Time
-	Body         string
-}
-
-type ListBucketResult struct {
-	Contents []BucketItem
-}
-
-func (b *Bucket) ListS3BucketObjects(prefix string) (result *ListBucketResult, err error) {
-	resp, err := b.ListS3BucketObjectsResponse(prefix)
-	if err != nil {
-		return
-	}
-
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return
-	}
-
-	if resp.StatusCode != 200 {
-		err = errors.New(string(body))
-		return
-	}
-
-	result = &ListBucketResult{}
-
-	err = xml.Unmarshal(body, result)
-	if err != nil {
-		return
-	}
-
-	return
-}
-
 func (b *Bucket) PutS3ObjectResponse(key string, body []byte) (*http.Response, error) {
-	fullPath := "http://" + b.Name + b.Base + key
-	req, err := http.NewRequest("PUT", fullPath, nil)
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key
+	req, err := http.NewRequest("PUT", fullPath, bytes.NewReader(body))
 	if err != nil {
 		return &http.Response{}, err
 	}
 
 	req.Header.Add("Content-Type", "text/plain")
 
-	req.Body = ioutil.NopCloser(bytes.NewReader(body))
-	req.ContentLength = int64(len(body))
-
 	return b.authDoRequest(req)
 }
 
@@ -235,8 +255,8 @@ func (b *Bucket) PutS3Object(key string, bs []byte) error {
 }
 
 func (b *Bucket) PutS3ObjectMetaDataResponse(key string, body []byte, data map[string]string) (*http.Response, error) {
-	fullPath := "http://" + b.Name + b.Base + key
-	req, err := http.NewRequest("PUT", fullPath, nil)
+	fullPath := b.scheme() + "://" + b.Name + b.Base + key
+	req, err := http.NewRequest("PUT", fullPath, bytes.NewReader(body))
 	if err != nil {
 		return &http.Response{}, err
 	}
@@ -247,9 +267,6 @@ func (b *Bucket) PutS3ObjectMetaDataResponse(key string, body []byte, data map[s
 		req.Header.Add("x-amz-meta-"+k, v)
 	}
 
-	req.Body = ioutil.NopCloser(bytes.NewReader(body))
-	req.ContentLength = int64(len(body))
-
 	return b.authDoRequest(req)
 }
 
@@ -274,26 +291,59 @@ func (b *Bucket) PutS3ObjectWithMetaData(key string, bs []byte, data map[string]
 }
 
 func (b *Bucket) authDoRequest(request *http.Request) (resp *http.Response, err error) {
-	b.authRequest(request)
-
-	start   := time.Now()
-  timeout := start.Add(TotalAttemptTimeout)
-  for attempt := 1; attempt <= MaxAttempts; attempt++ {
-    resp, err = http.DefaultClient.Do(request)
-    if err == nil {
-      break
-    }
-    if time.Now().After(timeout) {
-      break
-    }
-    time.Sleep(DelayBetweenAttempts)
-  }
+	client := b.httpClient()
+	timeout := time.Now().Add(b.totalAttemptTimeout())
+	sleep := time.Duration(0)
+
+	for attempt := 1; attempt <= b.maxAttempts(); attempt++ {
+		resetAuthHeaders(request)
+		b.authRequest(request)
+
+		resp, err = client.Do(request)
+		if err == nil && !shouldRetry(resp) {
+			return resp, err
+		}
+
+		if attempt == b.maxAttempts() || time.Now().After(timeout) {
+			break
+		}
+
+		if err == nil {
+			resp.Body.Close()
+		}
+
+		if request.Body != nil {
+			if request.GetBody == nil {
+				// The body has already been consumed and can't be
+				// rewound, so resending would ship an empty/partial
+				// request. Stop and surface what we have.
+				break
+			}
+			if request.Body, err = request.GetBody(); err != nil {
+				break
+			}
+		}
+
+		sleep = decorrelatedJitter(sleep)
+		time.Sleep(sleep)
+	}
 
 	return resp, err
 }
 
-// http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html#ConstructingTheAuthenticationHeader
+// authRequest signs request using the Bucket's configured
+// SignatureVersion.
 func (b *Bucket) authRequest(request *http.Request) {
+	if b.SignatureVersion == SigV4 {
+		b.authRequestV4(request)
+		return
+	}
+
+	b.authRequestV2(request)
+}
+
+// http://docs.aws.amazon.com/AmazonS3/latest/dev/RESTAuthentication.html#ConstructingTheAuthenticationHeader
+func (b *Bucket) authRequestV2(request *http.Request) {
 	if request.Header.Get("Date") == "" {
 		date := time.Now().UTC().Format(time.RFC1123Z)
 		request.Header.Add("Date", date)
@@ -311,7 +361,8 @@ func (b *Bucket) authRequest(request *http.Request) {
 	}, "")
 
 	stringToSign := strings.Join([]string{
-		request.Method, "\n\n", // no MD5
+		request.Method, "\n",
+		request.Header.Get("Content-MD5"), "\n",
 		request.Header.Get("Content-Type"), "\n",
 		request.Header.Get("Date"), "\n",
 		canonicalizedAmzHeaders(request.Header),
@@ -323,7 +374,7 @@ func (b *Bucket) authRequest(request *http.Request) {
 
 	signature := base64.StdEncoding.EncodeToString(h.Sum([]byte{}))
 
-	request.Header.Add("Host", "http://"+b.Name+b.Base)
+	request.Header.Add("Host", b.scheme()+"://"+b.Name+b.Base)
 	request.Header.Add("Authorization", "AWS "+b.Key+":"+signature)
 
 	return
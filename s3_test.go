@@ -33,10 +33,11 @@ func S3SetHandler(ctx *web.Context, key string) (ret string) {
 func init() {
 	FakeS3 = make(map[string]string)
 	TestBucket = &Bucket{
-		"localhost:7777",
-		"/",
-		"WhatEvenISComputerz",
-		"ADogWalkedInToABarAndOrderADrinkJKHePoopedHesADog",
+		Name:   "localhost:7777",
+		Base:   "/",
+		Key:    "WhatEvenISComputerz",
+		Secret: "ADogWalkedInToABarAndOrderADrinkJKHePoopedHesADog",
+		Scheme: "http",
 	}
 
 	S3Server = web.NewServer()